@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// Section is a single page of the card, e.g. "info" or "contact".
+type Section struct {
+	Name  string   `yaml:"name" toml:"name" json:"name"`
+	Body  string   `yaml:"body" toml:"body" json:"body"`
+	Links []string `yaml:"links,omitempty" toml:"links,omitempty" json:"links,omitempty"`
+	Art   string   `yaml:"art,omitempty" toml:"art,omitempty" json:"art,omitempty"`
+}
+
+// cardConfig is the on-disk shape of the config file.
+type cardConfig struct {
+	Sections []Section `yaml:"sections" toml:"sections"`
+}
+
+func (c cardConfig) validate() error {
+	if len(c.Sections) == 0 {
+		return fmt.Errorf("config has no sections")
+	}
+	seen := make(map[string]struct{}, len(c.Sections))
+	for i, s := range c.Sections {
+		if s.Name == "" {
+			return fmt.Errorf("section %d: name is required", i)
+		}
+		if s.Body == "" {
+			return fmt.Errorf("section %q: body is required", s.Name)
+		}
+		if _, dup := seen[s.Name]; dup {
+			return fmt.Errorf("section %q: duplicate name", s.Name)
+		}
+		seen[s.Name] = struct{}{}
+	}
+	return nil
+}
+
+// defaultSections is the minimal built-in card used when no config file
+// can be loaded, so the server still has something to show.
+func defaultSections() []Section {
+	return []Section{
+		{
+			Name: "info",
+			Body: `I'm a senior software engineer who loves to tinker with code across the board. Most of my time goes into building stuff with TypeScript, React, and Next.js, but I also dive into PHP for WordPress plugins when needed. On the side, I mess around with C++ and Go just for fun—keeps things interesting and keeps me learning.`,
+		},
+		{
+			Name: "contact",
+			Body: "cmateusz@protonmail.com",
+		},
+	}
+}
+
+func loadCardConfig(path string) ([]Section, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	var cfg cardConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse config: %w", err)
+		}
+	default:
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse config: %w", err)
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg.Sections, nil
+}
+
+// cardStore holds the currently active sections and the last load error
+// (if any), and is safe for concurrent reads from every active TUI session
+// while a watcher goroutine reloads it in the background.
+type cardStore struct {
+	mu       sync.RWMutex
+	sections []Section
+	loadErr  error
+}
+
+func newCardStore(path string) *cardStore {
+	cs := &cardStore{}
+	cs.reload(path)
+	return cs
+}
+
+func (cs *cardStore) reload(path string) {
+	sections, err := loadCardConfig(path)
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err != nil {
+		cs.loadErr = err
+		if cs.sections == nil {
+			cs.sections = defaultSections()
+		}
+		return
+	}
+	cs.sections = sections
+	cs.loadErr = nil
+}
+
+func (cs *cardStore) Sections() []Section {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.sections
+}
+
+func (cs *cardStore) Err() error {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.loadErr
+}
+
+// watchCardConfig watches path for changes and reloads cs whenever the file
+// is written, renamed onto, or recreated (common with editors that write
+// via a temp file + rename). It logs but does not return reload errors;
+// cs.Err() surfaces the last one for the TUI footer.
+func watchCardConfig(path string, cs *cardStore) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create watcher: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("watch %s: %w", dir, err)
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(path) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					cs.reload(path)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return watcher, nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandFunc handles one REPL command. It returns the model to continue
+// with (commands may mutate e.g. the theme or selection), an optional
+// tea.Cmd, and the text to print to the output log.
+type commandFunc func(m model, args []string) (model, tea.Cmd, string)
+
+// commandTable is the command dispatcher: registering a new command here is
+// all that's needed to expose it from the REPL, no changes to Update.
+// Built in init rather than a map literal, since cmdHelp ranges over
+// commandTable and a literal referencing cmdHelp would be an initialization
+// cycle.
+var commandTable map[string]commandFunc
+
+func init() {
+	commandTable = map[string]commandFunc{
+		"help":      cmdHelp,
+		"ls":        cmdLs,
+		"cat":       cmdCat,
+		"contact":   cmdContact,
+		"theme":     cmdTheme,
+		"guestbook": cmdGuestbook,
+		"quit":      cmdQuit,
+	}
+}
+
+func cmdHelp(m model, args []string) (model, tea.Cmd, string) {
+	names := make([]string, 0, len(commandTable))
+	for name := range commandTable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return m, nil, "available commands: " + strings.Join(names, ", ")
+}
+
+func cmdLs(m model, args []string) (model, tea.Cmd, string) {
+	names := make([]string, 0, len(m.sections))
+	for _, section := range m.sections {
+		names = append(names, section.Name)
+	}
+	return m, nil, strings.Join(names, "  ")
+}
+
+func cmdCat(m model, args []string) (model, tea.Cmd, string) {
+	if len(args) == 0 {
+		return m, nil, "usage: cat <section>"
+	}
+	section, ok := findSection(m.sections, args[0])
+	if !ok {
+		return m, nil, fmt.Sprintf("no such section %q, try `ls`", args[0])
+	}
+	return m, nil, renderSectionText(section)
+}
+
+func cmdContact(m model, args []string) (model, tea.Cmd, string) {
+	section, ok := findSection(m.sections, "contact")
+	if !ok {
+		return m, nil, "no contact section configured"
+	}
+	return m, nil, renderSectionText(section)
+}
+
+func cmdTheme(m model, args []string) (model, tea.Cmd, string) {
+	if len(args) == 0 {
+		return m, nil, fmt.Sprintf("current theme: %s (available: %s)", m.themeName, strings.Join(m.themeOrder, ", "))
+	}
+	next := m.applyTheme(args[0])
+	if next.themeName != args[0] {
+		return m, nil, fmt.Sprintf("no such theme %q, try: %s", args[0], strings.Join(m.themeOrder, ", "))
+	}
+	return next, nil, fmt.Sprintf("switched to theme %q", args[0])
+}
+
+func cmdGuestbook(m model, args []string) (model, tea.Cmd, string) {
+	if len(args) == 0 {
+		return m, nil, "usage: guestbook write <message>"
+	}
+	switch args[0] {
+	case "write":
+		if len(args) < 2 {
+			return m, nil, "usage: guestbook write <message>"
+		}
+		msg := strings.Join(args[1:], " ")
+		if _, err := m.guestbook.Add(m.fingerprint, m.nick, msg); err != nil {
+			return m, nil, err.Error()
+		}
+		return m, nil, "signed the guestbook"
+	case "read":
+		return m, nil, m.renderGuestbookEntries()
+	default:
+		return m, nil, "usage: guestbook write <message>"
+	}
+}
+
+func cmdQuit(m model, args []string) (model, tea.Cmd, string) {
+	return m, tea.Quit, ""
+}
+
+func findSection(sections []Section, name string) (Section, bool) {
+	for _, section := range sections {
+		if strings.EqualFold(section.Name, name) {
+			return section, true
+		}
+	}
+	return Section{}, false
+}
+
+func renderSectionText(section Section) string {
+	var b strings.Builder
+	if section.Art != "" {
+		b.WriteString(section.Art)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(section.Body)
+	for _, link := range section.Links {
+		b.WriteString("\n")
+		b.WriteString(link)
+	}
+	return b.String()
+}
+
+// runCommand parses and dispatches a command line entered in command mode.
+func runCommand(m model, line string) (model, tea.Cmd, string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return m, nil, ""
+	}
+	fn, ok := commandTable[fields[0]]
+	if !ok {
+		return m, nil, fmt.Sprintf("unknown command %q, try `help`", fields[0])
+	}
+	return fn(m, fields[1:])
+}
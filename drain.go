@@ -0,0 +1,121 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// drainMsg is broadcast to every active session when the server is about
+// to restart, so each model can switch into a read-only countdown banner
+// instead of being cut off mid-keystroke.
+type drainMsg struct {
+	deadline time.Time
+	message  string
+}
+
+// drainTickMsg redraws the countdown once a second while draining.
+type drainTickMsg struct{}
+
+func drainTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return drainTickMsg{} })
+}
+
+// drainBroadcaster fans a drainMsg out to every session's Bubble Tea
+// program. Each session subscribes its own channel and unsubscribes when
+// the SSH session closes.
+type drainBroadcaster struct {
+	mu       sync.Mutex
+	subs     map[chan drainMsg]struct{}
+	draining bool
+	last     drainMsg
+}
+
+func newDrainBroadcaster() *drainBroadcaster {
+	return &drainBroadcaster{subs: make(map[chan drainMsg]struct{})}
+}
+
+// subscribe registers a new session. If a drain is already underway - the
+// session connected during the shutdown window - it is handed the
+// in-progress drainMsg immediately instead of being left to believe it's a
+// normal session.
+func (d *drainBroadcaster) subscribe() chan drainMsg {
+	ch := make(chan drainMsg, 1)
+	d.mu.Lock()
+	d.subs[ch] = struct{}{}
+	if d.draining {
+		ch <- d.last
+	}
+	d.mu.Unlock()
+	return ch
+}
+
+func (d *drainBroadcaster) unsubscribe(ch chan drainMsg) {
+	d.mu.Lock()
+	delete(d.subs, ch)
+	d.mu.Unlock()
+	close(ch)
+}
+
+// Broadcast sends msg to every subscribed session, dropping it for any
+// session whose channel is already full rather than blocking.
+func (d *drainBroadcaster) Broadcast(msg drainMsg) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.draining = true
+	d.last = msg
+	for ch := range d.subs {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// Count returns the number of sessions still subscribed, used as a proxy
+// for "sessions still connected" while draining.
+func (d *drainBroadcaster) Count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.subs)
+}
+
+// waitForDrain turns a subscription channel into a tea.Cmd: it blocks
+// until a drainMsg arrives (or the channel is closed on session end) and
+// delivers it into the Bubble Tea Update loop.
+func waitForDrain(ch chan drainMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// drainAndWait broadcasts the shutdown banner and blocks until either
+// every session has voluntarily disconnected or timeout elapses, giving
+// operators a zero-downtime-ish restart behind a supervisor.
+func drainAndWait(drain *drainBroadcaster, timeout time.Duration, message string) {
+	if drain.Count() == 0 {
+		return
+	}
+	log.Info("Draining sessions before shutdown", "timeout", timeout, "sessions", drain.Count())
+	drain.Broadcast(drainMsg{deadline: time.Now().Add(timeout), message: message})
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-ticker.C:
+			if drain.Count() == 0 {
+				return
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
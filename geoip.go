@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// geoipLookup wraps an optional MaxMind GeoLite2-Country database used to
+// label sessions by country in metrics and the access log. A nil
+// *geoipLookup (or one with no reader) is valid and just reports
+// "unknown" for every address, since GeoIP is opt-in via --geoip-db.
+type geoipLookup struct {
+	reader *geoip2.Reader
+}
+
+func openGeoIP(path string) (*geoipLookup, error) {
+	if path == "" {
+		return nil, nil
+	}
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &geoipLookup{reader: reader}, nil
+}
+
+func (g *geoipLookup) Close() error {
+	if g == nil || g.reader == nil {
+		return nil
+	}
+	return g.reader.Close()
+}
+
+// Country returns the ISO country code for ip, or "unknown" if GeoIP isn't
+// configured or the address can't be resolved.
+func (g *geoipLookup) Country(ip net.IP) string {
+	if g == nil || g.reader == nil || ip == nil {
+		return "unknown"
+	}
+	record, err := g.reader.Country(ip)
+	if err != nil || record.Country.IsoCode == "" {
+		return "unknown"
+	}
+	return record.Country.IsoCode
+}
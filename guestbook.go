@@ -0,0 +1,167 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	_ "modernc.org/sqlite"
+)
+
+// ErrRateLimited is returned by Guestbook.Add when a fingerprint posts
+// again before rateLimitWindow has elapsed.
+var ErrRateLimited = errors.New("guestbook: posting too fast, slow down")
+
+const (
+	guestbookRateLimitWindow = 30 * time.Second
+	guestbookMaxMessageLen   = 280
+)
+
+// GuestbookEntry is one signed message left by a visitor.
+type GuestbookEntry struct {
+	ID          int64
+	Fingerprint string
+	Nick        string
+	Message     string
+	CreatedAt   time.Time
+}
+
+// Guestbook persists visitor entries in SQLite and rate-limits posting per
+// pubkey fingerprint.
+type Guestbook struct {
+	db *sql.DB
+
+	mu       sync.Mutex
+	lastPost map[string]time.Time
+}
+
+// openGuestbook opens (and migrates) the SQLite database at path.
+func openGuestbook(path string) (*Guestbook, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open guestbook db: %w", err)
+	}
+	const schema = `
+CREATE TABLE IF NOT EXISTS entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	fingerprint TEXT NOT NULL,
+	nick TEXT NOT NULL,
+	message TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate guestbook db: %w", err)
+	}
+	return &Guestbook{db: db, lastPost: make(map[string]time.Time)}, nil
+}
+
+func (g *Guestbook) Close() error {
+	return g.db.Close()
+}
+
+// stripControlChars removes Unicode control characters (category Cc),
+// including ESC, from s. Nick and message are both rendered verbatim into
+// every visitor's terminal, so stripping ESC here neutralizes ANSI escape
+// sequences (CSI, OSC, ...) before they ever reach storage.
+func stripControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Add inserts a new entry on behalf of fingerprint, rejecting it with
+// ErrRateLimited if that fingerprint posted within guestbookRateLimitWindow.
+func (g *Guestbook) Add(fingerprint, nick, message string) (GuestbookEntry, error) {
+	nick = stripControlChars(strings.TrimSpace(nick))
+	message = stripControlChars(strings.TrimSpace(message))
+	if message == "" {
+		return GuestbookEntry{}, fmt.Errorf("guestbook: empty message")
+	}
+	if utf8.RuneCountInString(message) > guestbookMaxMessageLen {
+		runes := []rune(message)
+		message = string(runes[:guestbookMaxMessageLen])
+	}
+
+	g.mu.Lock()
+	if last, ok := g.lastPost[fingerprint]; ok {
+		if wait := guestbookRateLimitWindow - time.Since(last); wait > 0 {
+			g.mu.Unlock()
+			return GuestbookEntry{}, ErrRateLimited
+		}
+	}
+	g.lastPost[fingerprint] = time.Now()
+	g.mu.Unlock()
+
+	entry := GuestbookEntry{
+		Fingerprint: fingerprint,
+		Nick:        nick,
+		Message:     message,
+		CreatedAt:   time.Now(),
+	}
+	res, err := g.db.Exec(
+		`INSERT INTO entries (fingerprint, nick, message, created_at) VALUES (?, ?, ?, ?)`,
+		entry.Fingerprint, entry.Nick, entry.Message, entry.CreatedAt,
+	)
+	if err != nil {
+		return GuestbookEntry{}, fmt.Errorf("insert entry: %w", err)
+	}
+	entry.ID, _ = res.LastInsertId()
+	return entry, nil
+}
+
+// Recent returns up to n entries, oldest first, for display in a scroller.
+func (g *Guestbook) Recent(n int) ([]GuestbookEntry, error) {
+	rows, err := g.db.Query(
+		`SELECT id, fingerprint, nick, message, created_at FROM entries ORDER BY id DESC LIMIT ?`, n,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []GuestbookEntry
+	for rows.Next() {
+		var e GuestbookEntry
+		if err := rows.Scan(&e.ID, &e.Fingerprint, &e.Nick, &e.Message, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, rows.Err()
+}
+
+// Delete removes an entry by ID. Callers are responsible for checking that
+// the requester is an admin.
+func (g *Guestbook) Delete(id int64) error {
+	_, err := g.db.Exec(`DELETE FROM entries WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete entry %d: %w", id, err)
+	}
+	return nil
+}
+
+// adminFingerprints loads the moderator fingerprint allowlist from the
+// given environment variable, e.g. GUESTBOOK_ADMINS="SHA256:abc,SHA256:def".
+func adminFingerprints(envVar string) map[string]struct{} {
+	admins := make(map[string]struct{})
+	for _, fp := range strings.Split(os.Getenv(envVar), ",") {
+		fp = strings.TrimSpace(fp)
+		if fp != "" {
+			admins[fp] = struct{}{}
+		}
+	}
+	return admins
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestGuestbook(t *testing.T) *Guestbook {
+	t.Helper()
+	g, err := openGuestbook(filepath.Join(t.TempDir(), "guestbook.db"))
+	if err != nil {
+		t.Fatalf("openGuestbook: %v", err)
+	}
+	t.Cleanup(func() { g.Close() })
+	return g
+}
+
+func TestGuestbookAdd(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		wantErr     bool
+		wantMessage string
+	}{
+		{
+			name:    "empty after trim is rejected",
+			message: "   \t  ",
+			wantErr: true,
+		},
+		{
+			name:        "short message stored as-is",
+			message:     "hello from the guestbook",
+			wantMessage: "hello from the guestbook",
+		},
+		{
+			name:        "message exactly at the limit is untouched",
+			message:     strings.Repeat("a", guestbookMaxMessageLen),
+			wantMessage: strings.Repeat("a", guestbookMaxMessageLen),
+		},
+		{
+			name:        "overlong ascii message is truncated to the limit",
+			message:     strings.Repeat("a", guestbookMaxMessageLen+10),
+			wantMessage: strings.Repeat("a", guestbookMaxMessageLen),
+		},
+		{
+			name:        "truncation falls on a rune boundary, not a byte offset",
+			message:     strings.Repeat("a", guestbookMaxMessageLen-1) + "€€€",
+			wantMessage: strings.Repeat("a", guestbookMaxMessageLen-1) + "€",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGuestbook(t)
+			entry, err := g.Add("SHA256:test", "nick", tt.message)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Add(%q) = nil error, want one", tt.message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Add(%q): %v", tt.message, err)
+			}
+			if entry.Message != tt.wantMessage {
+				t.Fatalf("Add(%q) message = %q, want %q", tt.message, entry.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestGuestbookAddStripsControlChars(t *testing.T) {
+	g := newTestGuestbook(t)
+	entry, err := g.Add("SHA256:test", "nick\x1b]0;pwned\x07", "hello\x1b[31m world")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if strings.ContainsAny(entry.Nick, "\x1b\x07") {
+		t.Fatalf("Nick = %q, still contains control characters", entry.Nick)
+	}
+	if strings.ContainsAny(entry.Message, "\x1b\x07") {
+		t.Fatalf("Message = %q, still contains control characters", entry.Message)
+	}
+	if entry.Message != "hello[31m world" {
+		t.Fatalf("Message = %q, want %q", entry.Message, "hello[31m world")
+	}
+}
+
+func TestGuestbookAddRateLimit(t *testing.T) {
+	g := newTestGuestbook(t)
+
+	if _, err := g.Add("SHA256:test", "nick", "first post"); err != nil {
+		t.Fatalf("first Add: %v", err)
+	}
+
+	if _, err := g.Add("SHA256:test", "nick", "second post"); err != ErrRateLimited {
+		t.Fatalf("second Add error = %v, want ErrRateLimited", err)
+	}
+
+	// A different fingerprint is not subject to the first one's limit.
+	if _, err := g.Add("SHA256:other", "nick", "from someone else"); err != nil {
+		t.Fatalf("other fingerprint Add: %v", err)
+	}
+
+	// Backdate the rate-limit entry to simulate the window having elapsed.
+	g.mu.Lock()
+	g.lastPost["SHA256:test"] = time.Now().Add(-guestbookRateLimitWindow - time.Second)
+	g.mu.Unlock()
+
+	if _, err := g.Add("SHA256:test", "nick", "third post"); err != nil {
+		t.Fatalf("Add after window elapsed: %v", err)
+	}
+}
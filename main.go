@@ -3,15 +3,20 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
@@ -21,18 +26,59 @@ import (
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/charmbracelet/wish/logging"
 	"github.com/mitchellh/go-wordwrap"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 const (
 	host = "0.0.0.0"
 	port = "23234"
+
+	guestbookAdminEnvVar = "GUESTBOOK_ADMINS"
 )
 
 func main() {
+	configPath := flag.String("config", "/data/card.yaml", "path to the card content config file")
+	guestbookPath := flag.String("guestbook-db", "/data/guestbook.db", "path to the guestbook SQLite database")
+	themesDir := flag.String("themes-dir", "/data/themes", "directory of additional *.toml theme files")
+	geoipPath := flag.String("geoip-db", "", "optional path to a MaxMind GeoLite2-Country database")
+	drainTimeout := flag.Duration("drain-timeout", 30*time.Second, "how long to wait for sessions to disconnect on their own after SIGTERM before cutting them off")
+	drainMessage := flag.String("drain-message", "server restarting in %ds, please reconnect", "message shown in the drain banner; %d is replaced with seconds remaining")
+	flag.Parse()
+
+	cards := newCardStore(*configPath)
+	if err := cards.Err(); err != nil {
+		log.Warn("Falling back to built-in card", "error", err)
+	}
+	watcher, err := watchCardConfig(*configPath, cards)
+	if err != nil {
+		log.Warn("Config hot-reload disabled", "error", err)
+	} else {
+		defer watcher.Close()
+	}
+
+	guestbook, err := openGuestbook(*guestbookPath)
+	if err != nil {
+		log.Fatal("Could not open guestbook", "error", err)
+	}
+	defer guestbook.Close()
+	admins := adminFingerprints(guestbookAdminEnvVar)
+
+	themes, themeOrder := loadThemes(*themesDir)
+	themePrefs := newThemeStore()
+
+	geo, err := openGeoIP(*geoipPath)
+	if err != nil {
+		log.Warn("GeoIP disabled", "error", err)
+	}
+	defer geo.Close()
+
+	drain := newDrainBroadcaster()
+
 	go func() {
 		fs := http.FileServer(http.Dir("/app/static"))
 		http.Handle("/", fs)
-		log.Info("Serving static HTML on :80")
+		servePrometheus()
+		log.Info("Serving static HTML, /metrics and /healthz on :80")
 		err := http.ListenAndServe(":80", nil)
 		if err != nil {
 			log.Fatalf("HTTP server failed: %v", err)
@@ -41,10 +87,26 @@ func main() {
 	s, err := wish.NewServer(
 		wish.WithAddress(net.JoinHostPort(host, port)),
 		wish.WithHostKeyPath("/data/key"),
+		// Registering a PublicKeyHandler, even one that accepts every key,
+		// disables wish/gliderlabs' "none"-auth fallback (it only applies
+		// when no auth handlers are registered at all), so a client with no
+		// key of its own would otherwise be rejected before ever seeing the
+		// card. WithKeyboardInteractiveAuth accepting unconditionally, with
+		// no challenge, gives those clients the same zero-friction access
+		// "none" auth would have, while clients that do offer a key still
+		// hit WithPublicKeyAuth and get fingerprinted for the guestbook.
+		wish.WithPublicKeyAuth(func(ctx ssh.Context, key ssh.PublicKey) bool {
+			return true // capture the fingerprint for the guestbook; accept everyone
+		}),
+		wish.WithKeyboardInteractiveAuth(func(ctx ssh.Context, challenger gossh.KeyboardInteractiveChallenge) bool {
+			return true // keyless fallback so clients with no key aren't locked out
+		}),
 		wish.WithMiddleware(
-			bubbletea.Middleware(teaHandler),
+			bubbletea.Middleware(teaHandler(cards, guestbook, admins, themes, themeOrder, themePrefs, drain)),
 			activeterm.Middleware(),
+			plainTextMiddleware(cards),
 			logging.Middleware(),
+			metricsMiddleware(geo),
 		),
 	)
 	if err != nil {
@@ -60,7 +122,10 @@ func main() {
 			done <- nil
 		}
 	}()
-	<-done
+	sig := <-done
+	if sig == syscall.SIGTERM {
+		drainAndWait(drain, *drainTimeout, *drainMessage)
+	}
 	log.Info("Stopping SSH server")
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer func() { cancel() }()
@@ -69,30 +134,90 @@ func main() {
 	}
 }
 
-func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
-	pty, _, _ := s.Pty()
+// sessionFingerprint returns the SHA256 fingerprint of the session's
+// offered public key. Keyless sessions are common (wish accepts
+// connections with no key), so they're identified by remote address
+// instead of a shared "anonymous" literal - otherwise every keyless
+// visitor would collapse onto one guestbook rate-limit bucket and one
+// theme preference.
+func sessionFingerprint(s ssh.Session) string {
+	if key := s.PublicKey(); key != nil {
+		return gossh.FingerprintSHA256(key)
+	}
+	return "anonymous:" + s.RemoteAddr().String()
+}
+
+func teaHandler(cards *cardStore, guestbook *Guestbook, admins map[string]struct{}, themes map[string]Theme, themeOrder []string, themePrefs *themeStore, drain *drainBroadcaster) bubbletea.Handler {
+	return func(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+		pty, _, _ := s.Pty()
+
+		drainCh := drain.subscribe()
+		go func() {
+			<-s.Context().Done()
+			drain.unsubscribe(drainCh)
+		}()
+
+		fingerprint := sessionFingerprint(s)
+		_, isAdmin := admins[fingerprint]
+
+		renderer := bubbletea.MakeRenderer(s)
+		themeName := themePrefs.Get(fingerprint)
+		theme, ok := themes[themeName]
+		if !ok {
+			theme = themes[defaultThemeName]
+			themeName = defaultThemeName
+		}
+		txtStyle, quitStyle, quitStyleDark, boxStyle, spin := themeStyles(renderer, theme)
 
-	renderer := bubbletea.MakeRenderer(s)
-	txtStyle := renderer.NewStyle().Foreground(lipgloss.Color("10"))
-	quitStyle := renderer.NewStyle().Foreground(lipgloss.Color("8"))
-	quitStyleDark := renderer.NewStyle().Foreground(lipgloss.Color("238"))
+		input := textinput.New()
+		input.Prompt = "> "
+		input.CharLimit = 256
 
-	spin := spinner.New()
-	spin.Spinner = spinner.Dot
-	spin.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
-	m := model{
-		width:         pty.Window.Width,
-		height:        pty.Window.Height,
-		txtStyle:      txtStyle,
-		quitStyle:     quitStyle,
-		quitStyleDark: quitStyleDark,
-		spinner:       spin,
-		options:       []string{"info", "contact"},
-		selected:      make(map[int]struct{}),
+		gbInput := textinput.New()
+		gbInput.Prompt = "say> "
+		gbInput.CharLimit = guestbookMaxMessageLen
+
+		m := model{
+			width:         pty.Window.Width,
+			height:        pty.Window.Height,
+			txtStyle:      txtStyle,
+			quitStyle:     quitStyle,
+			quitStyleDark: quitStyleDark,
+			boxStyle:      boxStyle,
+			spinner:       spin,
+			cards:         cards,
+			sections:      cards.Sections(),
+			selected:      make(map[int]struct{}),
+			input:         input,
+			guestbook:     guestbook,
+			gbInput:       gbInput,
+			fingerprint:   fingerprint,
+			nick:          s.User(),
+			isAdmin:       isAdmin,
+			renderer:      renderer,
+			themes:        themes,
+			themeOrder:    themeOrder,
+			themeName:     themeName,
+			themePrefs:    themePrefs,
+			drainCh:       drainCh,
+		}
+		return m, []tea.ProgramOption{tea.WithAltScreen()}
 	}
-	return m, []tea.ProgramOption{tea.WithAltScreen()}
 }
 
+// viewMode selects whether the session is driven by the cursor menu, the
+// command-mode REPL toggled with ":", or the guestbook.
+type viewMode int
+
+const (
+	menuMode viewMode = iota
+	commandMode
+	guestbookMode
+)
+
+// guestbookEntries is how many recent entries are shown in the scroller.
+const guestbookEntries = 50
+
 type model struct {
 	spinner       spinner.Model
 	width         int
@@ -100,13 +225,85 @@ type model struct {
 	txtStyle      lipgloss.Style
 	quitStyle     lipgloss.Style
 	quitStyleDark lipgloss.Style
-	options       []string
+	boxStyle      lipgloss.Style
+	cards         *cardStore
+	sections      []Section
 	cursor        int
 	selected      map[int]struct{}
+	mode          viewMode
+	input         textinput.Model
+	output        string
+
+	guestbook   *Guestbook
+	gbViewport  viewport.Model
+	gbInput     textinput.Model
+	gbErr       string
+	fingerprint string
+	nick        string
+	isAdmin     bool
+
+	renderer   *lipgloss.Renderer
+	themes     map[string]Theme
+	themeOrder []string
+	themeName  string
+	themePrefs *themeStore
+
+	drainCh       chan drainMsg
+	draining      bool
+	drainDeadline time.Time
+	drainMessage  string
+}
+
+// applyTheme rebuilds the renderer-scoped styles for name, persisting the
+// choice against m.fingerprint so it sticks across reconnects.
+func (m model) applyTheme(name string) model {
+	theme, ok := m.themes[name]
+	if !ok {
+		return m
+	}
+	m.themeName = name
+	m.txtStyle, m.quitStyle, m.quitStyleDark, m.boxStyle, m.spinner = themeStyles(m.renderer, theme)
+	m.themePrefs.Set(m.fingerprint, name)
+	return m
+}
+
+// nextTheme cycles to the next theme in themeOrder, wrapping around.
+func (m model) nextTheme() model {
+	if len(m.themeOrder) == 0 {
+		return m
+	}
+	idx := 0
+	for i, name := range m.themeOrder {
+		if name == m.themeName {
+			idx = i
+			break
+		}
+	}
+	next := m.themeOrder[(idx+1)%len(m.themeOrder)]
+	return m.applyTheme(next)
+}
+
+// menuRows returns the number of selectable menu rows: the config-driven
+// sections plus the trailing "guestbook" entry.
+func (m model) menuRows() int {
+	return len(m.sections) + 1
+}
+
+// isGuestbookRow reports whether i is the trailing "guestbook" menu row.
+func (m model) isGuestbookRow(i int) bool {
+	return i == len(m.sections)
+}
+
+// reloadTickMsg drives the periodic check for a config reload so an active
+// session picks up edits without the operator needing to reconnect it.
+type reloadTickMsg struct{}
+
+func reloadTick() tea.Cmd {
+	return tea.Tick(2*time.Second, func(time.Time) tea.Msg { return reloadTickMsg{} })
 }
 
 func (m model) Init() tea.Cmd {
-	return m.spinner.Tick
+	return tea.Batch(m.spinner.Tick, reloadTick(), waitForDrain(m.drainCh))
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -114,24 +311,66 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.height = msg.Height
 		m.width = msg.Width
+	case drainMsg:
+		m.draining = true
+		m.drainDeadline = msg.deadline
+		m.drainMessage = msg.message
+		return m, drainTick()
+	case drainTickMsg:
+		if !m.draining {
+			return m, nil
+		}
+		if time.Now().After(m.drainDeadline) {
+			return m, tea.Quit
+		}
+		return m, drainTick()
+	case reloadTickMsg:
+		m.sections = m.cards.Sections()
+		if m.cursor >= m.menuRows() {
+			m.cursor = m.menuRows() - 1
+		}
+		return m, reloadTick()
 	case tea.KeyMsg:
+		if m.draining {
+			return m, nil
+		}
+		if m.mode == commandMode {
+			return m.updateCommandMode(msg)
+		}
+		if m.mode == guestbookMode {
+			return m.updateGuestbookMode(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		case ":":
+			m.mode = commandMode
+			m.output = ""
+			m.input.Reset()
+			m.input.Focus()
+			return m, textinput.Blink
+		case "t":
+			m = m.nextTheme()
+			return m, nil
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
 			}
 		case "down", "j":
-			if m.cursor < len(m.options)-1 {
+			if m.cursor < m.menuRows()-1 {
 				m.cursor++
 			}
 		case "enter", " ":
+			if m.isGuestbookRow(m.cursor) {
+				menuSelectionsTotal.WithLabelValues("guestbook").Inc()
+				return m.enterGuestbookMode()
+			}
 			_, ok := m.selected[m.cursor]
 			if ok {
 				delete(m.selected, m.cursor)
 			} else {
 				m.selected[m.cursor] = struct{}{}
+				menuSelectionsTotal.WithLabelValues(m.sections[m.cursor].Name).Inc()
 			}
 		}
 	default:
@@ -142,14 +381,109 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateCommandMode handles key input while the session is in the
+// shell-like REPL, dispatching completed lines through commandTable.
+func (m model) updateCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = menuMode
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		line := m.input.Value()
+		m.input.Reset()
+		var cmd tea.Cmd
+		m, cmd, m.output = runCommand(m, line)
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// enterGuestbookMode switches the session into the guestbook, loading the
+// recent entries into the viewport scroller.
+func (m model) enterGuestbookMode() (tea.Model, tea.Cmd) {
+	m.mode = guestbookMode
+	m.gbErr = ""
+	m.gbViewport = viewport.New(m.width-10, m.height/2)
+	m.gbViewport.SetContent(m.renderGuestbookEntries())
+	m.gbViewport.GotoBottom()
+	m.gbInput.Reset()
+	m.gbInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m model) renderGuestbookEntries() string {
+	entries, err := m.guestbook.Recent(guestbookEntries)
+	if err != nil {
+		return fmt.Sprintf("could not load guestbook: %v", err)
+	}
+	if len(entries) == 0 {
+		return "no entries yet, be the first to sign the guestbook"
+	}
+	var s string
+	for _, e := range entries {
+		s += fmt.Sprintf("%s  %s: %s\n", e.CreatedAt.Format("Jan 2 15:04"), e.Nick, e.Message)
+		if m.isAdmin {
+			s += fmt.Sprintf("  (admin: `gbdelete %d` to remove)\n", e.ID)
+		}
+	}
+	return s
+}
+
+// updateGuestbookMode handles key input while viewing/composing guestbook
+// entries. "gbdelete <id>" is the moderator command for deleting an entry,
+// gated on the admin fingerprint allowlist.
+func (m model) updateGuestbookMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = menuMode
+		m.gbInput.Blur()
+		return m, nil
+	case "enter":
+		line := strings.TrimSpace(m.gbInput.Value())
+		m.gbInput.Reset()
+		if m.isAdmin {
+			if id, ok := strings.CutPrefix(line, "gbdelete "); ok {
+				if n, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64); err == nil {
+					if err := m.guestbook.Delete(n); err != nil {
+						m.gbErr = err.Error()
+					}
+				}
+				m.gbViewport.SetContent(m.renderGuestbookEntries())
+				m.gbViewport.GotoBottom()
+				return m, nil
+			}
+		}
+		if _, err := m.guestbook.Add(m.fingerprint, m.nick, line); err != nil {
+			m.gbErr = err.Error()
+		} else {
+			m.gbErr = ""
+		}
+		m.gbViewport.SetContent(m.renderGuestbookEntries())
+		m.gbViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.gbInput, cmd = m.gbInput.Update(msg)
+	return m, cmd
+}
+
 func (m model) View() string {
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		Padding(1, 2)
+	if m.draining {
+		return m.renderDrainBanner()
+	}
+	if m.mode == commandMode {
+		return m.renderCommandMode()
+	}
+	if m.mode == guestbookMode {
+		return m.renderGuestbookMode()
+	}
 
 	var s string
 
-	for i, choice := range m.options {
+	for i, section := range m.sections {
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
@@ -159,23 +493,35 @@ func (m model) View() string {
 		details := ""
 		if _, ok := m.selected[i]; ok {
 			checked = "x"
-			if m.options[i] == "info" {
-				details = `I'm a senior software engineer who loves to tinker with code across the board. Most of my time goes into building stuff with TypeScript, React, and Next.js, but I also dive into PHP for WordPress plugins when needed.On the side, I mess around with C++ and Go just for fun—keeps things interesting and keeps me learning.`
+			details = section.Body
+			for _, link := range section.Links {
+				details += "\n" + link
 			}
-			if m.options[i] == "contact" {
-				details += `cmateusz@protonmail.com`
+			if section.Art != "" {
+				details = section.Art + "\n\n" + details
 			}
 		}
-		s += fmt.Sprintf("%s [%s] %s\n", cursor, checked, choice)
+		s += fmt.Sprintf("%s [%s] %s\n", cursor, checked, section.Name)
 		if details != "" {
 			wrapped := wordwrap.WrapString(details, uint(m.width-10))
 			s += fmt.Sprintf("\n%s\n\n", wrapped)
 		}
 	}
+	guestbookCursor := " "
+	if m.isGuestbookRow(m.cursor) {
+		guestbookCursor = ">"
+	}
+	s += fmt.Sprintf("%s [ ] guestbook\n", guestbookCursor)
+
 	helpMsg := m.quitStyle.Render("j") + m.quitStyleDark.Render(" down · ")
 	helpMsg += m.quitStyle.Render("k") + m.quitStyleDark.Render(" up · ")
 	helpMsg += m.quitStyle.Render("spc") + m.quitStyleDark.Render(" select · ")
+	helpMsg += m.quitStyle.Render(":") + m.quitStyleDark.Render(" command · ")
+	helpMsg += m.quitStyle.Render("t") + m.quitStyleDark.Render(fmt.Sprintf(" theme (%s) · ", m.themeName))
 	helpMsg += m.quitStyle.Render("q") + m.quitStyleDark.Render(" quit ")
+	if err := m.cards.Err(); err != nil {
+		helpMsg += "\n" + m.quitStyle.Render(fmt.Sprintf("config error: %v (showing built-in card)", err))
+	}
 	content := fmt.Sprintf("\n%s czpl.dev WIP \n\n", m.spinner.View()) + m.txtStyle.Render(s) + "\n\n" + helpMsg
 
 	boxWidth := lipgloss.Width(content) + 4
@@ -185,5 +531,71 @@ func (m model) View() string {
 
 	return lipgloss.NewStyle().
 		Margin(yOffset, xOffset).
-		Render(boxStyle.Render(content))
+		Render(m.boxStyle.Render(content))
+}
+
+// renderDrainBanner replaces the normal view once the server has started
+// draining for a restart: a red countdown banner, independent of the
+// active theme, with no further input accepted.
+func (m model) renderDrainBanner() string {
+	remaining := int(time.Until(m.drainDeadline).Round(time.Second).Seconds())
+	if remaining < 0 {
+		remaining = 0
+	}
+	redStyle := m.renderer.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	content := redStyle.Render(fmt.Sprintf(m.drainMessage, remaining))
+
+	boxWidth := lipgloss.Width(content) + 4
+	boxHeight := lipgloss.Height(content) + 2
+	xOffset := (m.width - boxWidth) / 2
+	yOffset := (m.height - boxHeight) / 2
+
+	return lipgloss.NewStyle().
+		Margin(yOffset, xOffset).
+		Render(m.boxStyle.Render(content))
+}
+
+// renderCommandMode draws the REPL: the last command's output above a
+// textinput prompt, giving keyboard-first users a scriptable alternative
+// to the cursor menu.
+func (m model) renderCommandMode() string {
+	content := fmt.Sprintf("\n%s czpl.dev WIP \n\n", m.spinner.View())
+	if m.output != "" {
+		wrapped := wordwrap.WrapString(m.output, uint(m.width-10))
+		content += m.txtStyle.Render(wrapped) + "\n\n"
+	}
+	content += m.input.View()
+	content += "\n\n" + m.quitStyle.Render("esc") + m.quitStyleDark.Render(" back to menu · ") +
+		m.quitStyle.Render("help") + m.quitStyleDark.Render(" list commands ")
+
+	boxWidth := lipgloss.Width(content) + 4
+	boxHeight := lipgloss.Height(content) + 2
+	xOffset := (m.width - boxWidth) / 2
+	yOffset := (m.height - boxHeight) / 2
+
+	return lipgloss.NewStyle().
+		Margin(yOffset, xOffset).
+		Render(m.boxStyle.Render(content))
+}
+
+// renderGuestbookMode draws the scrollable guestbook viewport above a
+// textinput used to compose a new signed entry.
+func (m model) renderGuestbookMode() string {
+	content := fmt.Sprintf("\n%s czpl.dev guestbook \n\n", m.spinner.View())
+	content += m.gbViewport.View() + "\n\n"
+	if m.gbErr != "" {
+		content += m.quitStyle.Render(m.gbErr) + "\n\n"
+	}
+	content += m.gbInput.View()
+	content += "\n\n" + m.quitStyle.Render("esc") + m.quitStyleDark.Render(" back to menu · ") +
+		m.quitStyle.Render("enter") + m.quitStyleDark.Render(" sign ")
+
+	boxWidth := lipgloss.Width(content) + 4
+	boxHeight := lipgloss.Height(content) + 2
+	xOffset := (m.width - boxWidth) / 2
+	yOffset := (m.height - boxHeight) / 2
+
+	return lipgloss.NewStyle().
+		Margin(yOffset, xOffset).
+		Render(m.boxStyle.Render(content))
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	sessionsActive = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sshcard_sessions_active",
+		Help: "Number of SSH sessions currently open.",
+	})
+	sessionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "sshcard_session_duration_seconds",
+		Help:    "Duration of SSH sessions.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	})
+	sessionBytesSent = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "sshcard_session_bytes_sent",
+		Help:    "Bytes written to the client over the course of a session.",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	})
+	countriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sshcard_country_total",
+		Help: "Count of sessions by GeoIP country, when GeoIP is configured.",
+	}, []string{"country"})
+	menuSelectionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sshcard_menu_selections_total",
+		Help: "Count of menu items opened, by item name.",
+	}, []string{"item"})
+)
+
+// accessLog is a dedicated structured logger, one JSON line per session
+// close, meant to be piped into Loki/Elastic rather than read by a human.
+var accessLog = log.NewWithOptions(os.Stdout, log.Options{Formatter: log.JSONFormatter})
+
+// servePrometheus registers /metrics and /healthz on the default mux
+// alongside the static file server already started in main.
+func servePrometheus() {
+	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// countingSession wraps an ssh.Session to count bytes written to the
+// client, since bubbletea and the plain-text middleware both write
+// straight to the session.
+type countingSession struct {
+	ssh.Session
+	sent int64
+}
+
+func (c *countingSession) Write(p []byte) (int, error) {
+	n, err := c.Session.Write(p)
+	atomic.AddInt64(&c.sent, int64(n))
+	return n, err
+}
+
+// metricsMiddleware instruments every session: concurrent session count,
+// duration, bytes sent, and (if GeoIP is configured) country as Prometheus
+// series, plus a structured access-log line on close. Terminal size and
+// client version are attacker-controlled, unbounded strings, so they're
+// only ever written to the access log, never used as a Prometheus label -
+// using them as labels would let a client grow /metrics' cardinality
+// without bound just by reconnecting with a new value each time.
+func metricsMiddleware(geo *geoipLookup) wish.Middleware {
+	return func(sh ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			sessionsActive.Inc()
+			defer sessionsActive.Dec()
+			start := time.Now()
+
+			wrapped := &countingSession{Session: s}
+
+			terminalSize := "none"
+			if pty, _, ok := s.Pty(); ok {
+				terminalSize = fmt.Sprintf("%dx%d", pty.Window.Width, pty.Window.Height)
+			}
+
+			country := "unknown"
+			if host, _, err := net.SplitHostPort(s.RemoteAddr().String()); err == nil {
+				country = geo.Country(net.ParseIP(host))
+			}
+			countriesTotal.WithLabelValues(country).Inc()
+
+			sh(wrapped)
+
+			duration := time.Since(start)
+			sessionDuration.Observe(duration.Seconds())
+			sessionBytesSent.Observe(float64(wrapped.sent))
+
+			accessLog.Info("session closed",
+				"fingerprint", sessionFingerprint(s),
+				"user", s.User(),
+				"remote_addr", s.RemoteAddr().String(),
+				"client_version", s.Context().ClientVersion(),
+				"terminal_size", terminalSize,
+				"country", country,
+				"duration_seconds", duration.Seconds(),
+				"bytes_sent", wrapped.sent,
+			)
+		}
+	}
+}
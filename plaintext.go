@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// plainTextMiddleware serves non-interactive `ssh host <section>` requests
+// directly, without booting the Bubble Tea renderer, so the card can be
+// consumed by scripts and address-book importers rather than only humans
+// at a terminal. It must run before activeterm.Middleware so a session
+// with a command but no PTY isn't rejected before we get to see it. The
+// bypass triggers whenever a command was given or no PTY was requested -
+// a bare `ssh host` with no PTY (e.g. piped through another tool) gets the
+// plain-text usage message instead of being handed to the TUI or rejected.
+func plainTextMiddleware(cards *cardStore) wish.Middleware {
+	return func(sh ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			args := s.Command()
+			_, _, hasPty := s.Pty()
+			if len(args) == 0 && hasPty {
+				sh(s)
+				return
+			}
+
+			var rest []string
+			jsonOut := false
+			for _, a := range args {
+				if a == "--json" {
+					jsonOut = true
+					continue
+				}
+				rest = append(rest, a)
+			}
+			if len(rest) == 0 {
+				fmt.Fprintln(s, "usage: ssh czpl.dev <ls|vcard|section> [--json]")
+				s.Exit(1)
+				return
+			}
+
+			sections := cards.Sections()
+			switch rest[0] {
+			case "ls":
+				writeSectionList(s, sections, jsonOut)
+			case "vcard":
+				writeVCard(s, sections, jsonOut)
+			default:
+				section, ok := findSection(sections, rest[0])
+				if !ok {
+					fmt.Fprintf(s, "no such section %q, try `ssh czpl.dev ls`\n", rest[0])
+					s.Exit(1)
+					return
+				}
+				writeSection(s, section, jsonOut)
+			}
+			s.Exit(0)
+		}
+	}
+}
+
+func writeSectionList(s ssh.Session, sections []Section, jsonOut bool) {
+	if jsonOut {
+		names := make([]string, len(sections))
+		for i, section := range sections {
+			names[i] = section.Name
+		}
+		json.NewEncoder(s).Encode(names)
+		return
+	}
+	for _, section := range sections {
+		fmt.Fprintln(s, section.Name)
+	}
+}
+
+func writeSection(s ssh.Session, section Section, jsonOut bool) {
+	if jsonOut {
+		json.NewEncoder(s).Encode(section)
+		return
+	}
+	fmt.Fprintln(s, renderSectionText(section))
+}
+
+func writeVCard(s ssh.Session, sections []Section, jsonOut bool) {
+	card := buildVCard(sections)
+	if jsonOut {
+		json.NewEncoder(s).Encode(map[string]string{"vcard": card})
+		return
+	}
+	fmt.Fprint(s, card)
+}
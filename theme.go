@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the small palette applied to the TUI chrome: background, accent,
+// body text, muted/help text, the spinner, and the box border.
+type Theme struct {
+	Name       string `toml:"name"`
+	Background string `toml:"background"`
+	Accent     string `toml:"accent"`
+	Text       string `toml:"text"`
+	Muted      string `toml:"muted"`
+	Spinner    string `toml:"spinner"`
+	Border     string `toml:"border"`
+}
+
+const defaultThemeName = "dracula"
+
+func builtinThemeOrder() []string {
+	return []string{"dracula", "solarized-light", "gruvbox", "nord", "catppuccin"}
+}
+
+func builtinThemes() map[string]Theme {
+	return map[string]Theme{
+		"dracula": {
+			Name: "dracula", Background: "#282a36", Accent: "#bd93f9",
+			Text: "#f8f8f2", Muted: "#6272a4", Spinner: "#ff79c6", Border: "#44475a",
+		},
+		"solarized-light": {
+			Name: "solarized-light", Background: "#fdf6e3", Accent: "#268bd2",
+			Text: "#073642", Muted: "#93a1a1", Spinner: "#2aa198", Border: "#eee8d5",
+		},
+		"gruvbox": {
+			Name: "gruvbox", Background: "#282828", Accent: "#fe8019",
+			Text: "#ebdbb2", Muted: "#928374", Spinner: "#b8bb26", Border: "#504945",
+		},
+		"nord": {
+			Name: "nord", Background: "#2e3440", Accent: "#88c0d0",
+			Text: "#eceff4", Muted: "#4c566a", Spinner: "#81a1c1", Border: "#3b4252",
+		},
+		"catppuccin": {
+			Name: "catppuccin", Background: "#1e1e2e", Accent: "#f5c2e7",
+			Text: "#cdd6f4", Muted: "#6c7086", Spinner: "#94e2d5", Border: "#313244",
+		},
+	}
+}
+
+// loadThemes returns the built-in themes plus any *.toml files found in
+// dir, so operators can drop in their own palette at deploy time without
+// rebuilding the binary. A missing dir just falls back to the built-ins.
+func loadThemes(dir string) (map[string]Theme, []string) {
+	themes := builtinThemes()
+	order := builtinThemeOrder()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return themes, order
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+		var t Theme
+		if _, err := toml.DecodeFile(filepath.Join(dir, entry.Name()), &t); err != nil {
+			continue
+		}
+		if t.Name == "" {
+			t.Name = strings.TrimSuffix(entry.Name(), ".toml")
+		}
+		if _, exists := themes[t.Name]; !exists {
+			order = append(order, t.Name)
+		}
+		themes[t.Name] = t
+	}
+	return themes, order
+}
+
+// themeStore remembers each visitor's theme choice by pubkey fingerprint
+// for the life of the process.
+type themeStore struct {
+	mu     sync.RWMutex
+	chosen map[string]string
+}
+
+func newThemeStore() *themeStore {
+	return &themeStore{chosen: make(map[string]string)}
+}
+
+func (ts *themeStore) Get(fingerprint string) string {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	if name, ok := ts.chosen[fingerprint]; ok {
+		return name
+	}
+	return defaultThemeName
+}
+
+func (ts *themeStore) Set(fingerprint, name string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.chosen[fingerprint] = name
+}
+
+// themeStyles builds the renderer-scoped styles for theme. Using the
+// session's own renderer means termenv downgrades true color to whatever
+// the connecting client actually supports.
+func themeStyles(renderer *lipgloss.Renderer, theme Theme) (txtStyle, quitStyle, quitStyleDark, boxStyle lipgloss.Style, spin spinner.Model) {
+	txtStyle = renderer.NewStyle().Foreground(lipgloss.Color(theme.Text))
+	quitStyle = renderer.NewStyle().Foreground(lipgloss.Color(theme.Accent))
+	quitStyleDark = renderer.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	boxStyle = renderer.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Border)).
+		Padding(1, 2)
+
+	spin = spinner.New()
+	spin.Spinner = spinner.Dot
+	spin.Style = renderer.NewStyle().Foreground(lipgloss.Color(theme.Spinner))
+	return
+}
@@ -0,0 +1,54 @@
+package main
+
+import "strings"
+
+// buildVCard renders the card's sections as a vCard 3.0 payload: EMAIL is
+// scraped from the "contact" section, NOTE from the "info" section, so
+// address-book importers get something sensible out of the box.
+func buildVCard(sections []Section) string {
+	var note, email string
+	if contact, ok := findSection(sections, "contact"); ok {
+		email = extractEmail(contact)
+	}
+	if info, ok := findSection(sections, "info"); ok {
+		note = info.Body
+	}
+
+	var b strings.Builder
+	b.WriteString("BEGIN:VCARD\r\n")
+	b.WriteString("VERSION:3.0\r\n")
+	b.WriteString("FN:czpl.dev\r\n")
+	if email != "" {
+		b.WriteString("EMAIL:" + escapeVCardText(email) + "\r\n")
+	}
+	if note != "" {
+		b.WriteString("NOTE:" + escapeVCardText(note) + "\r\n")
+	}
+	b.WriteString("END:VCARD\r\n")
+	return b.String()
+}
+
+// extractEmail pulls the first @-containing token out of a section's body
+// and links, since sections are free-form prose rather than structured data.
+func extractEmail(section Section) string {
+	fields := strings.Fields(section.Body)
+	fields = append(fields, section.Links...)
+	for _, f := range fields {
+		f = strings.Trim(f, ".,;:<>")
+		f = strings.TrimPrefix(f, "mailto:")
+		if strings.Contains(f, "@") {
+			return f
+		}
+	}
+	return ""
+}
+
+// escapeVCardText escapes the characters the vCard 3.0 spec requires
+// escaped in free-text values.
+func escapeVCardText(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, ",", `\,`)
+	s = strings.ReplaceAll(s, ";", `\;`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}